@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/stretchrcom/goweb/webcontext"
+)
+
+// DefaultErrorHandler is the Handler used by HttpHandler to render an
+// error when no status-specific handler has been registered for it via
+// HttpHandler.RegisterErrorHandler.
+//
+// It renders the error as an RFC 7807 "problem details" document,
+// encoded through the request's CodecService so that JSON, XML and
+// MsgPack clients all receive the same canonical shape.
+type DefaultErrorHandler struct{}
+
+// WillHandle always returns true - the DefaultErrorHandler is happy to
+// render any error it is given.
+func (h *DefaultErrorHandler) WillHandle(ctx webcontext.WebContext) bool {
+	return true
+}
+
+// Handle renders the error found on ctx.Data().Get("error") as a
+// problem+json (or problem+xml, etc.) document.
+func (h *DefaultErrorHandler) Handle(ctx webcontext.WebContext) (bool, error) {
+
+	err, _ := ctx.Data().Get("error").(error)
+	if err == nil {
+		err = NewHTTPError(500, "Unknown error")
+	}
+
+	problem := NewProblemFromError(err, ctx.HttpRequest().URL.Path)
+
+	// make the raw error and the structured problem available to
+	// whatever runs after this handler (e.g. a request logger)
+	ctx.Data().Set("error", err)
+	ctx.Data().Set("problem", problem)
+
+	return true, writeProblem(ctx, problem)
+
+}
+
+// writeProblem encodes problem using the CodecService registered on ctx
+// and writes it to the response with the correct status code and
+// Content-Type header.
+func writeProblem(ctx webcontext.WebContext, problem *Problem) error {
+
+	contentType := contentTypeForResponding(ctx.HttpRequest())
+
+	codec, codecErr := ctx.CodecService().GetCodec(contentType)
+	if codecErr != nil {
+		return codecErr
+	}
+
+	bytes, marshalErr := codec.Marshal(problem, nil)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	responseWriter := ctx.HttpResponseWriter()
+	responseWriter.Header().Set("Content-Type", ProblemContentType(codec.ContentType()))
+	responseWriter.WriteHeader(problem.Status)
+	_, writeErr := responseWriter.Write(bytes)
+
+	return writeErr
+
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/stretchrcom/goweb/webcontext"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Func adapts a plain Go function into a Handler, taking care of
+// decoding the request body, calling the function, and encoding its
+// result - so handlers can be written as ordinary typed functions
+// instead of juggling webcontext.WebContext directly.
+//
+// The function given to NewFunc must take a context.Context, optionally
+// followed by a pointer to the request type, and return one of:
+//
+//	(out *Resp, status int, err error)
+//	(out *Resp, err error)
+//	(err error)
+//
+// When status isn't part of the signature, Handle defaults it to 200,
+// or 204 when out is nil.
+type Func struct {
+	value     reflect.Value
+	inType    reflect.Type // nil when the function takes no request
+	hasOut    bool
+	hasStatus bool
+}
+
+// NewFunc builds a Handler that calls fn, decoding its input (if any)
+// and encoding its output (if any) through the webcontext's
+// CodecService.
+//
+// NewFunc panics if fn's signature doesn't match one of the supported
+// shapes - this is considered a programming error, to be caught at
+// registration time rather than on the first request.
+func NewFunc(fn interface{}) *Func {
+
+	value := reflect.ValueOf(fn)
+	funcType := value.Type()
+
+	if funcType.Kind() != reflect.Func {
+		panic("handlers: NewFunc requires a function")
+	}
+
+	f := &Func{value: value}
+
+	switch funcType.NumIn() {
+	case 1:
+		if !funcType.In(0).Implements(contextType) {
+			panic("handlers: NewFunc's function must take a context.Context")
+		}
+	case 2:
+		if !funcType.In(0).Implements(contextType) {
+			panic("handlers: NewFunc's function must take a context.Context")
+		}
+		f.inType = funcType.In(1)
+		if f.inType.Kind() != reflect.Ptr {
+			panic("handlers: NewFunc's function must take a pointer to its request type, e.g. *Req")
+		}
+	default:
+		panic("handlers: NewFunc's function must take (context.Context) or (context.Context, *Req)")
+	}
+
+	switch funcType.NumOut() {
+	case 1:
+		if !funcType.Out(0).Implements(errorType) {
+			panic("handlers: NewFunc's function must return an error")
+		}
+	case 2:
+		f.hasOut = true
+		if !funcType.Out(1).Implements(errorType) {
+			panic("handlers: NewFunc's function must return (out, error)")
+		}
+	case 3:
+		f.hasOut = true
+		f.hasStatus = true
+		if funcType.Out(1).Kind() != reflect.Int {
+			panic("handlers: NewFunc's function must return (out, status, error)")
+		}
+		if !funcType.Out(2).Implements(errorType) {
+			panic("handlers: NewFunc's function must return (out, status, error)")
+		}
+	default:
+		panic("handlers: NewFunc's function must return (error), (out, error) or (out, status, error)")
+	}
+
+	return f
+
+}
+
+// WillHandle always returns true - Func adapts whatever function it was
+// given unconditionally; it's the route it's registered against that
+// decides when it applies.
+func (f *Func) WillHandle(ctx webcontext.WebContext) bool {
+	return true
+}
+
+// Handle decodes the request (if the function wants one), calls the
+// function, and encodes its result.
+func (f *Func) Handle(ctx webcontext.WebContext) (bool, error) {
+
+	args := []reflect.Value{reflect.ValueOf(ctx.HttpRequest().Context())}
+
+	if f.inType != nil {
+		in, err := f.decode(ctx)
+		if err != nil {
+			return true, wrapDecodeError(err)
+		}
+		args = append(args, in)
+	}
+
+	out, status, err := f.resultFrom(f.value.Call(args))
+	if err != nil {
+		return true, err
+	}
+
+	return true, writeOut(ctx, status, out)
+
+}
+
+// resultFrom applies the defaulting rules Handle promises - an error in
+// the last return value always wins, a declared status is used as is,
+// and otherwise out defaults to nil with a 204 status when the function
+// has no out value or returns a nil one, and to 200 otherwise - to the
+// raw reflect.Value results of calling f.
+func (f *Func) resultFrom(results []reflect.Value) (out interface{}, status int, err error) {
+
+	errValue := results[len(results)-1]
+	if !errValue.IsNil() {
+		return nil, 0, errValue.Interface().(error)
+	}
+
+	if !f.hasOut {
+		return nil, http.StatusNoContent, nil
+	}
+
+	out = results[0].Interface()
+
+	status = http.StatusOK
+	if f.hasStatus {
+		status = int(results[1].Int())
+	} else if isNilInterface(out) {
+		status = http.StatusNoContent
+	}
+
+	return out, status, nil
+
+}
+
+// wrapDecodeError turns a failure to decode the request body into the
+// *HTTPError Handle reports to the caller, preserving err as the cause
+// so it still surfaces in logs via the HTTPError's Error(). The
+// problem's "detail" rendered by DefaultErrorHandler is the fixed
+// message "Unable to decode request"; err's text is not part of it.
+func wrapDecodeError(err error) error {
+	return NewHTTPErrorWithCause(http.StatusBadRequest, "Unable to decode request", err)
+}
+
+// decode builds a new value of f.inType and populates it from the
+// request body using the CodecService, based on the request's
+// Content-Type.
+func (f *Func) decode(ctx webcontext.WebContext) (reflect.Value, error) {
+
+	inPtr := reflect.New(f.inType.Elem())
+
+	request := ctx.HttpRequest()
+	if request.Body == nil {
+		return inPtr, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return inPtr, err
+	}
+
+	if len(body) == 0 {
+		return inPtr, nil
+	}
+
+	codec, err := ctx.CodecService().GetCodec(contentTypeForRequest(request))
+	if err != nil {
+		return inPtr, err
+	}
+
+	if err := codec.Unmarshal(body, inPtr.Interface()); err != nil {
+		return inPtr, err
+	}
+
+	return inPtr, nil
+
+}
+
+// writeOut encodes out using Accept negotiation via the CodecService and
+// writes it to the response with the given status code.
+func writeOut(ctx webcontext.WebContext, status int, out interface{}) error {
+
+	responseWriter := ctx.HttpResponseWriter()
+
+	if isNilInterface(out) {
+		responseWriter.WriteHeader(status)
+		return nil
+	}
+
+	codec, err := ctx.CodecService().GetCodec(contentTypeForResponding(ctx.HttpRequest()))
+	if err != nil {
+		return err
+	}
+
+	bytes, err := codec.Marshal(out, nil)
+	if err != nil {
+		return err
+	}
+
+	responseWriter.Header().Set("Content-Type", codec.ContentType())
+	responseWriter.WriteHeader(status)
+	_, writeErr := responseWriter.Write(bytes)
+
+	return writeErr
+
+}
+
+// isNilInterface reports whether v is nil, or is a typed nil pointer,
+// map, slice or interface - the ways a concrete (out, ...) return value
+// can still mean "nothing to encode".
+func isNilInterface(v interface{}) bool {
+
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return false
+
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNewFuncAcceptsSupportedSignatures(t *testing.T) {
+
+	type req struct{}
+	type resp struct{}
+
+	signatures := []interface{}{
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context, in *req) error { return nil },
+		func(ctx context.Context, in *req) (*resp, error) { return nil, nil },
+		func(ctx context.Context, in *req) (*resp, int, error) { return nil, 0, nil },
+	}
+
+	for _, fn := range signatures {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					t.Errorf("NewFunc should have accepted %T, panicked with %v", fn, recovered)
+				}
+			}()
+			NewFunc(fn)
+		}()
+	}
+
+}
+
+func TestNewFuncPanicsOnNonPointerRequestType(t *testing.T) {
+
+	type req struct{}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFunc should panic at registration time when the request type isn't a pointer")
+		}
+	}()
+
+	NewFunc(func(ctx context.Context, in req) error { return nil })
+
+}
+
+func TestNewFuncPanicsOnMissingContext(t *testing.T) {
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFunc should panic when the function doesn't take a context.Context first")
+		}
+	}()
+
+	NewFunc(func(in string) error { return nil })
+
+}
+
+// errValueOf builds the error reflect.Value that reflect.Value.Call would
+// hand back for a function's (declared-interface) error return, since
+// constructing one from a concrete error via reflect.ValueOf alone would
+// carry the concrete type instead - resultFrom's IsNil check needs the
+// interface-typed value Call actually produces.
+func errValueOf(err error) reflect.Value {
+	v := reflect.New(errorType).Elem()
+	if err != nil {
+		v.Set(reflect.ValueOf(err))
+	}
+	return v
+}
+
+func TestFuncResultFromStatusDefaulting(t *testing.T) {
+
+	type resp struct{}
+
+	outErr := NewFunc(func(ctx context.Context) (*resp, error) { return nil, nil })
+	outStatusErr := NewFunc(func(ctx context.Context) (*resp, int, error) { return nil, 0, nil })
+	errOnly := NewFunc(func(ctx context.Context) error { return nil })
+
+	cases := []struct {
+		name       string
+		f          *Func
+		results    []reflect.Value
+		wantOut    interface{}
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:       "(out, error) with a non-nil out defaults to 200",
+			f:          outErr,
+			results:    []reflect.Value{reflect.ValueOf(&resp{}), errValueOf(nil)},
+			wantOut:    &resp{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "(out, error) with a nil out defaults to 204",
+			f:          outErr,
+			results:    []reflect.Value{reflect.ValueOf((*resp)(nil)), errValueOf(nil)},
+			wantOut:    (*resp)(nil),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:    "(out, error) with a non-nil error short-circuits out and status",
+			f:       outErr,
+			results: []reflect.Value{reflect.ValueOf((*resp)(nil)), errValueOf(errors.New("boom"))},
+			wantErr: errors.New("boom"),
+		},
+		{
+			name:       "(out, status, error) reports the declared status verbatim",
+			f:          outStatusErr,
+			results:    []reflect.Value{reflect.ValueOf(&resp{}), reflect.ValueOf(http.StatusAccepted), errValueOf(nil)},
+			wantOut:    &resp{},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "(error) always reports 204 with a nil out",
+			f:          errOnly,
+			results:    []reflect.Value{errValueOf(nil)},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			out, status, err := c.f.resultFrom(c.results)
+
+			if c.wantErr != nil {
+				if err == nil || err.Error() != c.wantErr.Error() {
+					t.Fatalf("err = %v, want %v", err, c.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(out, c.wantOut) {
+				t.Errorf("out = %#v, want %#v", out, c.wantOut)
+			}
+			if status != c.wantStatus {
+				t.Errorf("status = %d, want %d", status, c.wantStatus)
+			}
+
+		})
+	}
+
+}
+
+func TestWrapDecodeErrorMapsToBadRequest(t *testing.T) {
+
+	cause := errors.New("unexpected end of JSON input")
+
+	err := wrapDecodeError(cause)
+
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("wrapDecodeError returned %T, want *HTTPError", err)
+	}
+
+	if httpErr.StatusCode() != http.StatusBadRequest {
+		t.Errorf("StatusCode() = %d, want %d", httpErr.StatusCode(), http.StatusBadRequest)
+	}
+
+	if httpErr.Cause != cause {
+		t.Errorf("Cause = %v, want %v", httpErr.Cause, cause)
+	}
+
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import "fmt"
+
+// HTTPError is an error that carries the HTTP status code it should be
+// reported with, along with an optional human readable message and the
+// underlying cause (if any).
+//
+// Pre, process and post Handlers may return an *HTTPError instead of a
+// plain error so that HttpHandler.ServeHTTP can pick the right registered
+// error handler (see HttpHandler.RegisterErrorHandler) and render a
+// correctly-coded response.
+type HTTPError struct {
+
+	// Code is the HTTP status code that should be used for the response.
+	Code int
+
+	// Message is a short, human readable description of what went wrong.
+	// It is used to populate the "detail" field of the problem document.
+	Message string
+
+	// Cause is the underlying error that triggered this HTTPError, if any.
+	Cause error
+}
+
+// NewHTTPError makes a new HTTPError with the given status code and
+// message.
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+// NewHTTPErrorWithCause makes a new HTTPError that wraps the given cause.
+func NewHTTPErrorWithCause(code int, message string, cause error) *HTTPError {
+	return &HTTPError{Code: code, Message: message, Cause: cause}
+}
+
+// Error makes HTTPError satisfy the error interface.
+func (e *HTTPError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Cause.Error())
+	}
+	return e.Message
+}
+
+// StatusCode gets the HTTP status code that this error should be reported
+// with.
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
@@ -5,6 +5,7 @@ import (
 	codecservices "github.com/stretchrcom/codecs/services"
 	"github.com/stretchrcom/goweb/webcontext"
 	"net/http"
+	"runtime/debug"
 	"strings"
 )
 
@@ -20,6 +21,14 @@ type HttpHandler struct {
 
 	// errorHandler represents the Handler that will be used to handle errors.
 	errorHandler Handler
+
+	// errorHandlers holds the Handlers registered via RegisterErrorHandler,
+	// keyed by the HTTP status code they should handle.
+	errorHandlers map[int]Handler
+
+	// onStatusHandlers holds the Handlers registered via OnStatus, keyed
+	// by the HTTP status code they should handle.
+	onStatusHandlers map[int]Handler
 }
 
 func NewHttpHandler(codecService codecservices.CodecService) *HttpHandler {
@@ -40,26 +49,153 @@ func (handler *HttpHandler) CodecService() codecservices.CodecService {
 	return handler.codecService
 }
 
-// ServeHTTP servers the 
+// ServeHTTP servers the
 func (handler *HttpHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
 
+	// buffer the response so that a status registered via OnStatus can
+	// still replace it after the fact, even if a handler already wrote
+	// to it
+	recorder := NewResponseRecorder(responseWriter)
+
 	// make the context
-	ctx := webcontext.NewWebContext(responseWriter, request, handler.codecService)
+	ctx := webcontext.NewWebContext(recorder, request, handler.codecService)
+
+	// run it through the pre and process handlers, recovering from any
+	// panic so that a single bad request can never take down the
+	// goroutine serving it - a panicking handler is reported through the
+	// same error path as one that simply returns an error. The post
+	// handlers run separately, below, once the final error/status is
+	// known - see the comment on handle for why.
+	err := handler.handle(ctx)
+
+	// a streaming handler (e.g. SSEHandler) commits the recorder itself
+	// as soon as it starts writing, so by the time it returns there's
+	// nothing left to rewrite - just get its output out the door
+	if !recorder.Committed() {
+
+		// do we need to handle an error?
+		if err != nil {
+
+			// set the error
+			ctx.Data().Set("error", err)
+
+			// a status registered via OnStatus or RegisterErrorHandler
+			// takes precedence over the default error handler
+			errorHandler := handler.errorHandlerFor(StatusCodeForError(err))
+
+			recorder.Reset()
+
+			// tell the handler to handle it
+			errorHandler.Handle(ctx)
+
+		} else if onStatusHandler, ok := handler.onStatusHandlers[recorder.Status()]; ok {
+
+			// a handler didn't error, but wrote a status that has a
+			// registered OnStatus handler - discard what it wrote and
+			// let the registered handler produce the response instead
+			recorder.Reset()
+
+			onStatusHandler.Handle(ctx)
+
+		}
+
+	}
+
+	// the status is now final - make it available to the post handlers
+	// (e.g. a RequestLogger) alongside the error and problem that the
+	// error/OnStatus handling above may have set
+	ctx.Data().Set("status", recorder.Status())
+
+	handler.PostHandlersPipe().Handle(ctx)
+
+	recorder.Flush()
+
+}
 
-	// run it through the handlers
-	_, err := handler.Handlers.Handle(ctx)
+// handle runs ctx through the pre and process handlers, recovering from
+// any panic a downstream Handler raises and reporting it as a 500
+// *HTTPError, with the stack trace attached to ctx.Data() so a logging
+// post-handler can pick it up.
+//
+// The post handlers are deliberately not run as part of this: they need
+// to see the error and status that ServeHTTP works out afterwards (a
+// downstream error or status may still be replaced by a registered
+// error/OnStatus handler), so ServeHTTP runs them itself once that's
+// settled instead of letting them run as just another stage of the pipe.
+func (handler *HttpHandler) handle(ctx webcontext.WebContext) (err error) {
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			ctx.Data().Set("stack", string(debug.Stack()))
+			err = NewHTTPErrorWithCause(500, "Internal server error", fmt.Errorf("%v", recovered))
+		}
+	}()
+
+	stop, err := handler.PreHandlersPipe().Handle(ctx)
+	if err != nil || stop {
+		return err
+	}
+
+	_, err = handler.HandlersPipe().Handle(ctx)
+
+	return err
+
+}
+
+// errorHandlerFor gets the Handler that should be used to render an error
+// reported with the given HTTP status code.  A Handler registered via
+// OnStatus wins over one registered via RegisterErrorHandler for the
+// same code, since OnStatus is the more specific of the two; the default
+// ErrorHandler is used if neither has been registered.
+func (handler *HttpHandler) errorHandlerFor(statusCode int) Handler {
+
+	if onStatus, ok := handler.onStatusHandlers[statusCode]; ok {
+		return onStatus
+	}
 
-	// do we need to handle an error?
-	if err != nil {
+	if registered, ok := handler.errorHandlers[statusCode]; ok {
+		return registered
+	}
+
+	return handler.ErrorHandler()
 
-		// set the error
-		ctx.Data().Set("error", err)
+}
 
-		// tell the handler to handle it
-		handler.ErrorHandler().Handle(ctx)
+// OnStatus registers a Handler to run whenever a response's status code
+// is, or becomes, statusCode - whether a downstream Handler wrote that
+// status directly (e.g. NotFound()) or it's the status an error was
+// mapped to. Whatever the original handler had already written is
+// discarded in favour of onStatusHandler's output.
+//
+// This lets an application ship one uniform 404, 401 or 500 page (or
+// JSON body) across every route, without every handler having to
+// remember to render it itself.
+func (handler *HttpHandler) OnStatus(statusCode int, onStatusHandler Handler) {
 
+	if handler.onStatusHandlers == nil {
+		handler.onStatusHandlers = make(map[int]Handler)
 	}
 
+	handler.onStatusHandlers[statusCode] = onStatusHandler
+
+}
+
+// RegisterErrorHandler registers a Handler to be used whenever an error
+// carrying the given HTTP status code (see HTTPError) reaches ServeHTTP,
+// instead of the default problem+json rendering.
+//
+// This lets applications customise the representation of specific
+// statuses (e.g. a branded 404 page, or a 401 that includes a
+// WWW-Authenticate header) without having to reimplement the whole
+// ErrorHandler.
+func (handler *HttpHandler) RegisterErrorHandler(statusCode int, errorHandler Handler) {
+
+	if handler.errorHandlers == nil {
+		handler.errorHandlers = make(map[int]Handler)
+	}
+
+	handler.errorHandlers[statusCode] = errorHandler
+
 }
 
 // ErrorHandler gets the Handler that will be used to handle errors.
@@ -83,7 +219,7 @@ func (h *HttpHandler) ErrorHandler() Handler {
 
 // SetErrorHandler sets the Handler that will be used to handle errors.
 //
-// The error handler is like a normal Handler, except with a few oddities. 
+// The error handler is like a normal Handler, except with a few oddities.
 // The WillHandle method never gets called on the ErrorHandler, and any errors
 // returned from the Handle method are ignored (as is the stop argument).
 // If you want to log errors, you should do so from within the ErrorHandler.
@@ -0,0 +1,33 @@
+// Package middleware provides small, composable pre and post Handlers
+// for concerns that cut across every route: request IDs and structured
+// request logging. Panic recovery is handled automatically by
+// HttpHandler.ServeHTTP itself, so it doesn't need a Handler here.
+package middleware
+
+import (
+	"log"
+)
+
+// Logger is the interface RequestLogger (and anything else in this
+// package that needs to emit a log line) writes through.  *log.Logger
+// has no Logf method and so does not satisfy it directly; applications
+// that don't already have a structured logger can wrap one with
+// NewStdLogger to get going.
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// stdLogger adapts a *log.Logger to the Logger interface.
+type stdLogger struct {
+	*log.Logger
+}
+
+// NewStdLogger adapts logger to the Logger interface expected by this
+// package.
+func NewStdLogger(logger *log.Logger) Logger {
+	return &stdLogger{Logger: logger}
+}
+
+func (l *stdLogger) Logf(format string, args ...interface{}) {
+	l.Printf(format, args...)
+}
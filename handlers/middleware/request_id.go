@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/stretchrcom/goweb/webcontext"
+)
+
+// RequestIDKey is the ctx.Data() key the request's ID is stored under.
+const RequestIDKey = "requestId"
+
+// requestStartedAtKey is the ctx.Data() key the time the request started
+// being handled is stored under, so RequestLogger can compute latency.
+const requestStartedAtKey = "requestStartedAt"
+
+// RequestIDHeader is the HTTP header a request ID is read from (if the
+// caller already has one, e.g. from an upstream proxy) and written back
+// to on the response.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID is a pre-handler that makes sure every request has a unique
+// ID: it uses the one supplied in the X-Request-ID header if the caller
+// sent one, generating a new one otherwise, and stores it on both
+// ctx.Data() (under RequestIDKey, for other handlers) and the response's
+// X-Request-ID header (so callers and downstream services can
+// correlate logs for this request).
+type RequestID struct{}
+
+// NewRequestID makes a RequestID pre-handler.
+func NewRequestID() *RequestID {
+	return new(RequestID)
+}
+
+// WillHandle always returns true - every request gets an ID.
+func (h *RequestID) WillHandle(ctx webcontext.WebContext) bool {
+	return true
+}
+
+// Handle assigns the request its ID and records when it started.
+func (h *RequestID) Handle(ctx webcontext.WebContext) (bool, error) {
+
+	id := ctx.HttpRequest().Header.Get(RequestIDHeader)
+	if id == "" {
+		id = generateRequestID()
+	}
+
+	ctx.Data().Set(RequestIDKey, id)
+	ctx.Data().Set(requestStartedAtKey, time.Now())
+	ctx.HttpResponseWriter().Header().Set(RequestIDHeader, id)
+
+	return false, nil
+
+}
+
+// generateRequestID makes a random, hex-encoded request ID. If the
+// random source fails, it uses the current time in nanoseconds, in
+// decimal, instead.
+func generateRequestID() string {
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return fmt.Sprintf("%x", buf)
+
+}
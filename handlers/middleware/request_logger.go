@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/stretchrcom/goweb/handlers"
+	"github.com/stretchrcom/goweb/webcontext"
+)
+
+// RequestLogger is a post-handler that emits one structured log line per
+// request: method, path, status, latency, request ID and error (if any).
+//
+// It relies on RequestID having run as a pre-handler to stamp the
+// request's ID and start time onto ctx.Data(); register both with
+// HttpHandler for this to produce complete log lines.
+//
+// Crucially, RequestLogger must be registered as a post-handler: by the
+// time HttpHandler.ServeHTTP runs the post pipe, it has already settled
+// on the request's final status - including anything a registered
+// OnStatus or RegisterErrorHandler handler rewrote it to - and stamped it
+// onto ctx.Data() under "status", which is what RequestLogger reads.
+type RequestLogger struct {
+	logger Logger
+}
+
+// NewRequestLogger makes a RequestLogger that writes through logger.
+func NewRequestLogger(logger Logger) *RequestLogger {
+	return &RequestLogger{logger: logger}
+}
+
+// WillHandle always returns true - every request gets logged.
+func (h *RequestLogger) WillHandle(ctx webcontext.WebContext) bool {
+	return true
+}
+
+// Handle emits the log line for the request that ctx represents.
+func (h *RequestLogger) Handle(ctx webcontext.WebContext) (bool, error) {
+
+	request := ctx.HttpRequest()
+
+	var errForLine error
+	if err, ok := ctx.Data().Get("error").(error); ok && err != nil {
+		errForLine = err
+	}
+
+	status, ok := ctx.Data().Get("status").(int)
+	if !ok {
+		// ServeHTTP always stamps "status" before running the post pipe;
+		// this is only reached when RequestLogger is driven directly,
+		// outside of HttpHandler.ServeHTTP
+		status = 200
+		if errForLine != nil {
+			status = handlers.StatusCodeForError(errForLine)
+		}
+	}
+
+	latency := time.Duration(0)
+	if startedAt, ok := ctx.Data().Get(requestStartedAtKey).(time.Time); ok {
+		latency = time.Since(startedAt)
+	}
+
+	requestID, _ := ctx.Data().Get(RequestIDKey).(string)
+
+	h.logger.Logf("%s %s %d %s request_id=%s error=%v",
+		request.Method, request.URL.Path, status, latency, requestID, errForLine)
+
+	return false, nil
+
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultContentType is the content type assumed when a request doesn't
+// send an Accept (or Content-Type) header at all.
+const DefaultContentType = "application/json"
+
+// contentTypeForRequest works out which content type an incoming request
+// is encoded with, defaulting to DefaultContentType when no Content-Type
+// header is present.
+func contentTypeForRequest(request *http.Request) string {
+
+	contentType := request.Header.Get("Content-Type")
+	if contentType == "" {
+		return DefaultContentType
+	}
+
+	// strip off any parameters (e.g. "; charset=utf-8")
+	if semicolon := strings.Index(contentType, ";"); semicolon != -1 {
+		contentType = contentType[:semicolon]
+	}
+
+	return strings.TrimSpace(contentType)
+
+}
+
+// contentTypeForResponding works out which content type should be used
+// to encode the response to request, based on its Accept header.
+//
+// This is deliberately simple: it takes the first type listed in Accept
+// other than "*/*" (ignoring quality parameters) and falls back to
+// DefaultContentType for an empty header or one listing only "*/*". The
+// type it returns is not checked against the codecs that are registered.
+func contentTypeForResponding(request *http.Request) string {
+
+	accept := request.Header.Get("Accept")
+	if accept == "" {
+		return DefaultContentType
+	}
+
+	for _, candidate := range strings.Split(accept, ",") {
+
+		candidate = strings.TrimSpace(candidate)
+		if semicolon := strings.Index(candidate, ";"); semicolon != -1 {
+			candidate = candidate[:semicolon]
+		}
+
+		if candidate == "" || candidate == "*/*" {
+			continue
+		}
+
+		return candidate
+
+	}
+
+	return DefaultContentType
+
+}
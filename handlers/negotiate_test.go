@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentTypeForRequest(t *testing.T) {
+
+	cases := map[string]string{
+		"":                               DefaultContentType,
+		"application/json":               "application/json",
+		"application/xml; charset=utf-8": "application/xml",
+	}
+
+	for header, want := range cases {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		if header != "" {
+			request.Header.Set("Content-Type", header)
+		}
+		if got := contentTypeForRequest(request); got != want {
+			t.Errorf("contentTypeForRequest(Content-Type: %q) = %q, want %q", header, got, want)
+		}
+	}
+
+}
+
+func TestContentTypeForResponding(t *testing.T) {
+
+	cases := map[string]string{
+		"":                      DefaultContentType,
+		"*/*":                   DefaultContentType,
+		"application/xml":       "application/xml",
+		"application/xml;q=0.9": "application/xml",
+		"*/*, application/json": "application/json",
+	}
+
+	for header, want := range cases {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			request.Header.Set("Accept", header)
+		}
+		if got := contentTypeForResponding(request); got != want {
+			t.Errorf("contentTypeForResponding(Accept: %q) = %q, want %q", header, got, want)
+		}
+	}
+
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Problem is the canonical "problem details" document described by
+// RFC 7807.  It is the shape that every error, whatever its source,
+// eventually gets mapped to before it is written to the response - so
+// that JSON, XML and MsgPack clients all see the same fields.
+type Problem struct {
+	Type     string `codec:"type" json:"type" xml:"type"`
+	Title    string `codec:"title" json:"title" xml:"title"`
+	Status   int    `codec:"status" json:"status" xml:"status"`
+	Detail   string `codec:"detail,omitempty" json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string `codec:"instance,omitempty" json:"instance,omitempty" xml:"instance,omitempty"`
+}
+
+// genericErrorDetail is the Detail used for a Problem built from a plain
+// error, i.e. one that didn't arrive as an *HTTPError. The underlying
+// error's own message is never put into a response: it might contain
+// anything from a SQL query to a file path, and RFC 7807 documents are
+// seen by API clients, not just logs.
+const genericErrorDetail = "An unexpected error occurred"
+
+// ProblemContentType derives the Content-Type that should be used when
+// writing a Problem encoded with codecContentType, as recommended by
+// RFC 7807 - e.g. "application/json" becomes "application/problem+json".
+// Content types this package doesn't recognise are passed through
+// unchanged.
+func ProblemContentType(codecContentType string) string {
+
+	switch {
+	case strings.Contains(codecContentType, "json"):
+		return "application/problem+json"
+	case strings.Contains(codecContentType, "xml"):
+		return "application/problem+xml"
+	default:
+		return codecContentType
+	}
+
+}
+
+// NewProblem makes a Problem for the given status code, using the
+// standard library's status text as the title.
+func NewProblem(status int, detail, instance string) *Problem {
+	return &Problem{
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	}
+}
+
+// NewProblemFromError builds a Problem that represents err.
+//
+// If err is an *HTTPError, its Code and Message are used to populate the
+// Status and Detail fields - these are assumed to already be safe to
+// show to a caller, since the application constructed them deliberately.
+// Any other error is treated as an unexpected, internal server error:
+// its Detail is a fixed, generic message rather than err.Error(), so
+// that whatever the error actually says (a SQL error, a file path, ...)
+// never reaches an API client. The real error is still available to
+// anything that needs it (e.g. for logging) via ctx.Data().Get("error").
+func NewProblemFromError(err error, instance string) *Problem {
+
+	if httpError, ok := err.(*HTTPError); ok {
+		return NewProblem(httpError.Code, httpError.Message, instance)
+	}
+
+	return NewProblem(http.StatusInternalServerError, genericErrorDetail, instance)
+
+}
+
+// StatusCodeForError works out which HTTP status code should be used to
+// report err, defaulting to 500 when err doesn't say otherwise.
+func StatusCodeForError(err error) int {
+
+	if httpError, ok := err.(*HTTPError); ok {
+		return httpError.Code
+	}
+
+	return http.StatusInternalServerError
+
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewProblemFromErrorUsesHTTPErrorMessage(t *testing.T) {
+
+	problem := NewProblemFromError(NewHTTPError(404, "No such widget"), "/widgets/1")
+
+	if problem.Status != 404 {
+		t.Errorf("Status should be 404, got %d", problem.Status)
+	}
+	if problem.Detail != "No such widget" {
+		t.Errorf("Detail should be the HTTPError's message, got %q", problem.Detail)
+	}
+
+}
+
+func TestNewProblemFromErrorHidesPlainErrorText(t *testing.T) {
+
+	err := errors.New("pq: password authentication failed for user \"admin\"")
+	problem := NewProblemFromError(err, "/widgets/1")
+
+	if problem.Status != http.StatusInternalServerError {
+		t.Errorf("Status should default to 500, got %d", problem.Status)
+	}
+	if problem.Detail == err.Error() {
+		t.Errorf("Detail should not be the raw error text")
+	}
+	if problem.Detail != genericErrorDetail {
+		t.Errorf("Detail should be the generic message, got %q", problem.Detail)
+	}
+
+}
+
+func TestProblemContentType(t *testing.T) {
+
+	cases := map[string]string{
+		"application/json":        "application/problem+json",
+		"application/json; q=0.9": "application/problem+json",
+		"application/xml":         "application/problem+xml",
+		"application/msgpack":     "application/msgpack",
+	}
+
+	for in, want := range cases {
+		if got := ProblemContentType(in); got != want {
+			t.Errorf("ProblemContentType(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+}
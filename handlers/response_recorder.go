@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// ResponseRecorder is a small http.ResponseWriter wrapper that buffers
+// everything written to it instead of sending it straight to the
+// underlying connection, so HttpHandler can inspect - and, if needed,
+// discard - a response before it ever reaches the client.
+//
+// This is what lets OnStatus rewrite a response after the fact: a
+// handler that calls responseWriter.WriteHeader(404) has that status
+// buffered rather than sent, giving HttpHandler the chance to Reset the
+// recorder and run the registered 404 handler in its place.
+//
+// Buffering the entire response is wrong for a streaming handler (e.g.
+// SSEHandler), which needs every write delivered immediately. Flush
+// accounts for this: the first call commits whatever has been written so
+// far to the underlying ResponseWriter and switches the recorder into a
+// pass-through mode, so OnStatus only ever gets a chance to rewrite a
+// response that hasn't started streaming yet.
+type ResponseRecorder struct {
+	underlying  http.ResponseWriter
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	committed   bool
+}
+
+// NewResponseRecorder makes a ResponseRecorder that will eventually
+// commit to underlying.
+func NewResponseRecorder(underlying http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{
+		underlying: underlying,
+		header:     make(http.Header),
+	}
+}
+
+// Header gets the buffered header map.
+func (r *ResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader buffers statusCode; it isn't sent to the underlying
+// ResponseWriter until the recorder is committed (see Flush).
+func (r *ResponseRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = statusCode
+	r.wroteHeader = true
+}
+
+// Write buffers b, implicitly recording a 200 status first if
+// WriteHeader hasn't been called yet - matching net/http's own
+// ResponseWriter behaviour. Once the recorder has been committed (see
+// Flush), writes go straight to the underlying ResponseWriter instead.
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.committed {
+		return r.underlying.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+// Status gets the status code buffered so far, defaulting to 200 if
+// nothing has written one yet.
+func (r *ResponseRecorder) Status() int {
+	if !r.wroteHeader {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Committed reports whether the recorder has already sent its header
+// and any buffered body to the underlying ResponseWriter. Once
+// committed, the response can no longer be Reset.
+func (r *ResponseRecorder) Committed() bool {
+	return r.committed
+}
+
+// Reset discards everything buffered so far - the header, body and
+// status - so a fresh response can be written in its place. Reset has no
+// effect once the recorder has been committed; by then the response has
+// already started reaching the client and can't be taken back.
+func (r *ResponseRecorder) Reset() {
+	if r.committed {
+		return
+	}
+	r.header = make(http.Header)
+	r.body.Reset()
+	r.status = 0
+	r.wroteHeader = false
+}
+
+// Flush commits the buffered header, status and body to the underlying
+// ResponseWriter (a no-op if that has already happened) and flushes the
+// underlying connection, if it supports that itself.
+//
+// Flush satisfies http.Flusher, so a streaming handler that type-asserts
+// its ResponseWriter for Flush support keeps working when that
+// ResponseWriter is a *ResponseRecorder - and, since committing makes
+// every later Write pass straight through, such a handler streams in
+// real time rather than buffering its whole response in memory.
+func (r *ResponseRecorder) Flush() {
+
+	r.commit()
+
+	if flusher, ok := r.underlying.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+}
+
+// commit sends the buffered header, status and body to the underlying
+// ResponseWriter, marking the recorder as committed so that subsequent
+// Writes go straight through instead of being buffered.
+func (r *ResponseRecorder) commit() {
+
+	if r.committed {
+		return
+	}
+
+	underlyingHeader := r.underlying.Header()
+	for key, values := range r.header {
+		underlyingHeader[key] = values
+	}
+
+	r.underlying.WriteHeader(r.Status())
+
+	if r.body.Len() > 0 {
+		r.underlying.Write(r.body.Bytes())
+		r.body.Reset()
+	}
+
+	r.committed = true
+
+}
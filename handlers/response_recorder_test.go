@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseRecorderBuffersUntilFlush(t *testing.T) {
+
+	underlying := httptest.NewRecorder()
+	recorder := NewResponseRecorder(underlying)
+
+	recorder.Header().Set("X-Test", "1")
+	recorder.WriteHeader(404)
+	recorder.Write([]byte("not found"))
+
+	if recorder.Status() != 404 {
+		t.Errorf("Status() should be 404, got %d", recorder.Status())
+	}
+
+	if underlying.Code != 200 {
+		t.Errorf("nothing should have reached the underlying ResponseWriter yet, got code %d", underlying.Code)
+	}
+
+	recorder.Flush()
+
+	if underlying.Code != 404 {
+		t.Errorf("Flush should commit the buffered status, got %d", underlying.Code)
+	}
+	if underlying.Body.String() != "not found" {
+		t.Errorf("Flush should commit the buffered body, got %q", underlying.Body.String())
+	}
+	if underlying.Header().Get("X-Test") != "1" {
+		t.Errorf("Flush should commit the buffered header")
+	}
+
+}
+
+func TestResponseRecorderResetDiscardsBufferedResponse(t *testing.T) {
+
+	underlying := httptest.NewRecorder()
+	recorder := NewResponseRecorder(underlying)
+
+	recorder.WriteHeader(500)
+	recorder.Write([]byte("boom"))
+
+	recorder.Reset()
+
+	if recorder.Status() != 200 {
+		t.Errorf("Reset should discard the buffered status, got %d", recorder.Status())
+	}
+
+	recorder.WriteHeader(200)
+	recorder.Write([]byte("ok"))
+	recorder.Flush()
+
+	if underlying.Body.String() != "ok" {
+		t.Errorf("only the post-Reset body should reach the underlying ResponseWriter, got %q", underlying.Body.String())
+	}
+
+}
+
+func TestResponseRecorderPassesThroughAfterFirstFlush(t *testing.T) {
+
+	underlying := httptest.NewRecorder()
+	recorder := NewResponseRecorder(underlying)
+
+	recorder.WriteHeader(200)
+	recorder.Write([]byte("first "))
+	recorder.Flush()
+
+	if underlying.Body.String() != "first " {
+		t.Fatalf("first Flush should have committed the initial write, got %q", underlying.Body.String())
+	}
+
+	recorder.Write([]byte("second"))
+
+	if underlying.Body.String() != "first second" {
+		t.Errorf("writes after the first Flush should pass straight through, got %q", underlying.Body.String())
+	}
+
+	if recorder.Committed() != true {
+		t.Errorf("recorder should report itself as committed once Flush has been called")
+	}
+
+	// Reset must not be able to discard a response that's already
+	// reached the client.
+	recorder.Reset()
+	if recorder.Status() != 200 {
+		t.Errorf("Reset should be a no-op once the recorder is committed")
+	}
+
+}
+
+func TestResponseRecorderSatisfiesHttpFlusher(t *testing.T) {
+	var _ http.Flusher = NewResponseRecorder(httptest.NewRecorder())
+}
@@ -0,0 +1,81 @@
+package sse
+
+import "sync"
+
+// subscriberBuffer is how many unconsumed Events a single subscriber
+// channel will buffer before Publish starts dropping events for it
+// rather than blocking the publisher.
+const subscriberBuffer = 16
+
+// Broker decouples SSEHandler from the transport used to fan events out
+// to subscribers.  The default, used when no Broker is set on an
+// SSEHandler, is an in-memory implementation; applications that need to
+// fan out across multiple processes can provide their own, backed by
+// Redis, NATS, or similar, by implementing this interface.
+type Broker interface {
+
+	// Subscribe registers interest in topic, returning a channel that
+	// will receive every Event subsequently Published to that topic,
+	// and an unsubscribe function that must be called to release the
+	// subscription once the caller is done with it.
+	Subscribe(topic string) (events <-chan Event, unsubscribe func())
+
+	// Publish sends event to every current subscriber of topic.
+	Publish(topic string, event Event)
+}
+
+// memoryBroker is the default, in-process Broker implementation.
+type memoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]bool
+}
+
+// NewMemoryBroker makes a Broker that fans events out to subscribers
+// within this process only.
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		subscribers: make(map[string]map[chan Event]bool),
+	}
+}
+
+func (b *memoryBroker) Subscribe(topic string) (<-chan Event, func()) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Event]bool)
+	}
+	b.subscribers[topic][events] = true
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[topic], events)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+		close(events)
+	}
+
+	return events, unsubscribe
+
+}
+
+func (b *memoryBroker) Publish(topic string, event Event) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers[topic] {
+		select {
+		case subscriber <- event:
+		default:
+			// subscriber isn't keeping up - drop the event rather than
+			// blocking every other subscriber and the publisher.
+		}
+	}
+
+}
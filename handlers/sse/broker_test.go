@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMemoryBrokerDeliversToSubscriber(t *testing.T) {
+
+	broker := NewMemoryBroker()
+
+	events, unsubscribe := broker.Subscribe("topic-a")
+	defer unsubscribe()
+
+	broker.Publish("topic-a", Event{Data: "hello"})
+
+	select {
+	case event := <-events:
+		if event.Data != "hello" {
+			t.Errorf("Data should be %q, got %q", "hello", event.Data)
+		}
+	default:
+		t.Fatal("subscriber should have received the published event")
+	}
+
+}
+
+func TestMemoryBrokerDoesNotDeliverToOtherTopics(t *testing.T) {
+
+	broker := NewMemoryBroker()
+
+	events, unsubscribe := broker.Subscribe("topic-a")
+	defer unsubscribe()
+
+	broker.Publish("topic-b", Event{Data: "hello"})
+
+	select {
+	case event := <-events:
+		t.Fatalf("subscriber to topic-a should not have received %+v", event)
+	default:
+	}
+
+}
+
+func TestMemoryBrokerUnsubscribeClosesChannel(t *testing.T) {
+
+	broker := NewMemoryBroker()
+
+	events, unsubscribe := broker.Subscribe("topic-a")
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("events channel should be closed after unsubscribe")
+	}
+
+}
+
+func TestSSEHandlerBrokerIsRaceFree(t *testing.T) {
+
+	handler := &SSEHandler{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.broker()
+			handler.topicFunc()
+		}()
+	}
+	wg.Wait()
+
+}
@@ -0,0 +1,59 @@
+package sse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a single Server-Sent Event, as described by the WHATWG HTML
+// specification for the text/event-stream content type.
+type Event struct {
+
+	// ID, if set, is sent as the event's "id" field and becomes the
+	// value the browser will report back as Last-Event-ID if the
+	// connection is dropped and retried.
+	ID string
+
+	// Event, if set, is sent as the event's "event" field, letting
+	// subscribers register listeners for specific named events.
+	Event string
+
+	// Data is the payload of the event.  Multi-line data is split across
+	// multiple "data:" fields, as required by the spec.
+	Data string
+
+	// Retry, if greater than zero, tells the client how long to wait
+	// before reconnecting after the connection is lost.
+	Retry time.Duration
+}
+
+// WriteTo writes e to w in the wire format expected by EventSource
+// clients, terminated by the blank line that marks the end of the event.
+func (e Event) WriteTo(w io.Writer) error {
+
+	var b strings.Builder
+
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry/time.Millisecond)
+	}
+
+	for _, line := range strings.Split(e.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+
+}
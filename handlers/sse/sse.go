@@ -0,0 +1,174 @@
+// Package sse provides a Server-Sent Events Handler that plugs into the
+// same Pipe model as the rest of goweb's handlers.
+package sse
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stretchrcom/goweb/handlers"
+	"github.com/stretchrcom/goweb/webcontext"
+)
+
+// DefaultHeartbeatInterval is how often a keep-alive comment is sent down
+// an idle connection, to stop intermediate proxies from timing it out.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// ReplayFunc is given the Last-Event-ID sent by a reconnecting client and
+// should return the Events that client missed, oldest first.
+type ReplayFunc func(topic, lastEventID string) []Event
+
+// TopicFunc works out which topic a request should be subscribed to.
+// The default derives the topic from the request path.
+type TopicFunc func(request *http.Request) string
+
+// SSEHandler is a Handler that upgrades a request to a long-lived
+// text/event-stream response and streams it every Event Published to the
+// topic the request resolves to.
+//
+// SSEHandler is intended to sit in the process pipe, after any auth
+// pre-handlers and before any metrics post-handlers: it owns the
+// response for the lifetime of the connection, so nothing downstream of
+// it in the process pipe, and nothing in the post pipe, may write to the
+// response themselves.
+type SSEHandler struct {
+
+	// Broker fans Published Events out to subscribers.  Defaults to an
+	// in-memory Broker if left nil.
+	Broker Broker
+
+	// HeartbeatInterval is how often a keep-alive comment is written to
+	// idle connections.  Defaults to DefaultHeartbeatInterval.
+	HeartbeatInterval time.Duration
+
+	// Topic works out the topic a request subscribes to. Defaults to
+	// the request's URL path.
+	Topic TopicFunc
+
+	// Replay, if set, is used to resend Events a reconnecting client
+	// missed, based on the Last-Event-ID header it sends.
+	Replay ReplayFunc
+
+	// brokerOnce and topicOnce guard the lazy defaulting of Broker and
+	// Topic in broker() and topicFunc(): a zero-value SSEHandler is
+	// registered once but Handle runs concurrently for every request, so
+	// assigning those fields on first use has to happen exactly once.
+	brokerOnce sync.Once
+	topicOnce  sync.Once
+}
+
+// NewSSEHandler makes an SSEHandler backed by an in-memory Broker, ready
+// to be registered with HttpHandler.AppendHandler.
+func NewSSEHandler() *SSEHandler {
+	return &SSEHandler{
+		Broker:            NewMemoryBroker(),
+		HeartbeatInterval: DefaultHeartbeatInterval,
+		Topic:             topicForRequestPath,
+	}
+}
+
+// topicForRequestPath is the default TopicFunc: one topic per request
+// path.
+func topicForRequestPath(request *http.Request) string {
+	return request.URL.Path
+}
+
+// WillHandle reports whether request asked to be upgraded to an
+// event-stream.
+func (h *SSEHandler) WillHandle(ctx webcontext.WebContext) bool {
+	return ctx.HttpRequest().Header.Get("Accept") == "text/event-stream"
+}
+
+// Publish sends event to every current subscriber of topic.
+func (h *SSEHandler) Publish(topic string, event Event) {
+	h.broker().Publish(topic, event)
+}
+
+// Handle hijacks the response and streams Events published to the
+// request's topic until the client disconnects.
+func (h *SSEHandler) Handle(ctx webcontext.WebContext) (bool, error) {
+
+	request := ctx.HttpRequest()
+	responseWriter := ctx.HttpResponseWriter()
+
+	flusher, ok := responseWriter.(http.Flusher)
+	if !ok {
+		return true, handlers.NewHTTPError(http.StatusInternalServerError, "sse: ResponseWriter doesn't support flushing")
+	}
+
+	header := responseWriter.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	responseWriter.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	topic := h.topicFunc()(request)
+
+	events, unsubscribe := h.broker().Subscribe(topic)
+	defer unsubscribe()
+
+	if lastEventID := request.Header.Get("Last-Event-ID"); lastEventID != "" && h.Replay != nil {
+		for _, missed := range h.Replay(topic, lastEventID) {
+			if err := missed.WriteTo(responseWriter); err != nil {
+				return true, err
+			}
+		}
+		flusher.Flush()
+	}
+
+	heartbeatInterval := h.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+
+		case <-request.Context().Done():
+			return true, nil
+
+		case event := <-events:
+			if err := event.WriteTo(responseWriter); err != nil {
+				return true, nil
+			}
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			if _, err := responseWriter.Write([]byte(": heartbeat\n\n")); err != nil {
+				return true, nil
+			}
+			flusher.Flush()
+
+		}
+	}
+
+}
+
+// broker gets the Broker to use, falling back to a fresh in-memory one
+// if none has been set. Safe to call concurrently from multiple
+// requests' Handle calls.
+func (h *SSEHandler) broker() Broker {
+	h.brokerOnce.Do(func() {
+		if h.Broker == nil {
+			h.Broker = NewMemoryBroker()
+		}
+	})
+	return h.Broker
+}
+
+// topicFunc gets the TopicFunc to use, falling back to one topic per
+// request path if none has been set. Safe to call concurrently from
+// multiple requests' Handle calls.
+func (h *SSEHandler) topicFunc() TopicFunc {
+	h.topicOnce.Do(func() {
+		if h.Topic == nil {
+			h.Topic = topicForRequestPath
+		}
+	})
+	return h.Topic
+}